@@ -0,0 +1,247 @@
+package depcheck
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Checker holds a fully compiled rule set and evaluates individual import
+// edges against it. It's the shared core behind both the go vet analyzer
+// (Analyzer/run, one package at a time) and the standalone depcheck CLI
+// (cmd/depcheck), which checks a whole program's import graph in one pass.
+type Checker struct {
+	rules        []compiledRule
+	globalIgnore ignoreMatcher
+	maxDepth     int
+	configRoot   string
+
+	transitive transitiveCache
+}
+
+// CheckOptions carries the CLI-style overrides (disable/enable-only/severity
+// flags) that apply on top of a rule's own configured severity.
+type CheckOptions struct {
+	Disabled         map[string]bool
+	EnableOnly       map[string]bool
+	SeverityOverride string
+}
+
+// Violation describes a single rule breach found for one import edge.
+type Violation struct {
+	RuleID     string
+	FromPkg    string
+	ImportPath string
+	Severity   string
+	// Chain is the transitive import chain from ImportPath down to the
+	// offending package, inclusive of both ends. It's nil for direct-mode
+	// rule violations, where ImportPath itself is the offending package.
+	Chain []string
+}
+
+// Message renders the violation the same way as the analyzer's diagnostics.
+func (v Violation) Message() string {
+	var message string
+	if v.Chain != nil {
+		full := append([]string{v.FromPkg}, v.Chain...)
+		message = fmt.Sprintf("invalid transitive dependency: %s", strings.Join(full, " -> "))
+	} else {
+		message = fmt.Sprintf("invalid dependency: %s", v.ImportPath)
+	}
+	if v.Severity == severityWarning {
+		message = "warning: " + message
+	}
+	return message
+}
+
+// LoadConfig finds and parses a depcheck YAML config, starting from
+// configPath (searched upward via findConfigFile). It returns the parsed
+// Config and the directory it was found in, which rules use to resolve
+// sibling files such as .depcheckignore.
+func LoadConfig(configPath string) (Config, string, error) {
+	foundPath, err := findConfigFile(configPath)
+	if err != nil {
+		return Config{}, "", err
+	}
+
+	data, err := os.ReadFile(foundPath)
+	if err != nil {
+		return Config{}, "", fmt.Errorf("could not read config file: %w", err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return Config{}, "", fmt.Errorf("could not parse config file: %w", err)
+	}
+
+	return config, filepath.Dir(foundPath), nil
+}
+
+// NewChecker compiles a Config loaded from configRoot (the directory
+// containing the config file) into a ready-to-use Checker.
+func NewChecker(config Config, configRoot string) (*Checker, error) {
+	patternSyntax := config.PatternSyntax
+	if patternSyntax == "" {
+		patternSyntax = patternSyntaxRegexp // preserve existing behavior for configs written before this field existed
+	}
+	if patternSyntax != patternSyntaxRegexp && patternSyntax != patternSyntaxGitignore {
+		return nil, fmt.Errorf("invalid patternSyntax %q: must be %q or %q", config.PatternSyntax, patternSyntaxRegexp, patternSyntaxGitignore)
+	}
+
+	// Compile global ignore patterns, plus any patterns from a sibling
+	// .depcheckignore file when using gitignore syntax.
+	globalLines := append([]string{}, config.IgnorePatterns...)
+	if patternSyntax == patternSyntaxGitignore {
+		extra, err := loadDepcheckIgnoreFile(configRoot)
+		if err != nil {
+			return nil, fmt.Errorf("could not read .depcheckignore: %w", err)
+		}
+		globalLines = append(globalLines, extra...)
+	}
+
+	globalMatcher, err := buildMatcher(patternSyntax, configRoot, globalLines)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ignore pattern: %v", err)
+	}
+
+	rules := make([]compiledRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		severity := rule.Severity
+		if severity == "" {
+			severity = severityError
+		}
+		if severity != severityError && severity != severityWarning && severity != severityOff {
+			return nil, fmt.Errorf("invalid severity %q for rule %q: must be error, warning, or off", rule.Severity, rule.ID)
+		}
+
+		mode := rule.Mode
+		if mode == "" {
+			mode = ruleModeDirect
+		}
+		if mode != ruleModeDirect && mode != ruleModeTransitive {
+			return nil, fmt.Errorf("invalid mode %q for rule %q: must be %q or %q", rule.Mode, rule.ID, ruleModeDirect, ruleModeTransitive)
+		}
+
+		ruleIgnoreMatcher, err := buildMatcher(patternSyntax, configRoot, rule.IgnorePatterns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern for rule %q: %v", rule.ID, err)
+		}
+
+		compiled := compiledRule{
+			from:                regexp.MustCompile(rule.From),
+			to:                  make([]*regexp.Regexp, 0, len(rule.To)),
+			allowedDependencies: make([]*regexp.Regexp, 0, len(rule.AllowedDependencies)),
+			ignorePatterns:      ruleIgnoreMatcher,
+			id:                  rule.ID,
+			severity:            severity,
+			mode:                mode,
+		}
+
+		for _, toPattern := range rule.To {
+			compiled.to = append(compiled.to, regexp.MustCompile(toPattern))
+		}
+		for _, allowedPattern := range rule.AllowedDependencies {
+			compiled.allowedDependencies = append(compiled.allowedDependencies, regexp.MustCompile(allowedPattern))
+		}
+
+		rules = append(rules, compiled)
+	}
+
+	return &Checker{
+		rules:        rules,
+		globalIgnore: globalMatcher,
+		maxDepth:     config.MaxDepth,
+		configRoot:   configRoot,
+	}, nil
+}
+
+// Rules exposes the Checker's compiled rule set to callers outside this
+// package (such as cmd/depcheck) that need to hand it to NewReporter.
+func (c *Checker) Rules() []compiledRule {
+	return c.rules
+}
+
+// CheckImport evaluates every compiled rule for a single import edge
+// (fromPkg, via the import at path, found in filename). allowRuleID/hasAllow
+// come from parsing the import's `depcheck:allow` comment. resolvePkg
+// resolves an import path to its *types.Package for mode: transitive rules;
+// pass nil if the caller can't supply one, which causes transitive rules to
+// be skipped for this edge.
+func (c *Checker) CheckImport(fromPkg, filename, path string, allowRuleID string, hasAllow bool, opts CheckOptions, resolvePkg func(path string) *types.Package) []Violation {
+	var violations []Violation
+
+	for ruleIndex, rule := range c.rules {
+		if !rule.from.MatchString(fromPkg) {
+			continue
+		}
+
+		// -depcheck.enable-only / -depcheck.disable take precedence over config
+		if len(opts.EnableOnly) > 0 && !opts.EnableOnly[rule.id] {
+			continue
+		}
+		if opts.Disabled[rule.id] {
+			continue
+		}
+
+		severity := resolveSeverity(rule.severity, opts.SeverityOverride)
+		if severity == severityOff {
+			continue
+		}
+
+		// A bare `depcheck:allow` silences every rule; `depcheck:allow=<id>`
+		// silences only the matching rule.
+		if hasAllow && (allowRuleID == "" || allowRuleID == rule.id) {
+			continue
+		}
+
+		if shouldIgnoreFile(filename, c.globalIgnore, rule.ignorePatterns) {
+			continue
+		}
+
+		if isAllowed(rule, path) {
+			continue
+		}
+
+		if rule.mode == ruleModeTransitive {
+			if resolvePkg == nil {
+				continue
+			}
+			directPkg := resolvePkg(path)
+			if directPkg == nil {
+				continue
+			}
+
+			chain := c.transitive.chain(ruleCacheKey(ruleIndex, rule), rule, path, directPkg)
+			if chain == nil || !withinMaxDepth(len(chain), c.maxDepth) {
+				continue
+			}
+
+			violations = append(violations, Violation{
+				RuleID:     rule.id,
+				FromPkg:    fromPkg,
+				ImportPath: path,
+				Severity:   severity,
+				Chain:      chain,
+			})
+			continue
+		}
+
+		for _, toPattern := range rule.to {
+			if toPattern.MatchString(path) {
+				violations = append(violations, Violation{
+					RuleID:     rule.id,
+					FromPkg:    fromPkg,
+					ImportPath: path,
+					Severity:   severity,
+				})
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,153 @@
+// Command depcheck validates dependency rules across a whole program's
+// import graph, starting from one or more entrypoints declared in the YAML
+// config. Unlike the go vet analyzer (depcheck.Analyzer), which only sees
+// one package at a time, this loads the full reachable graph up front so a
+// rule can span arbitrarily many packages in one pass.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/v-standard/go-depcheck"
+)
+
+func main() {
+	configPath := flag.String("config", "depcheck.yml", "path to the depcheck YAML config")
+	jsonOutput := flag.Bool("json", false, "emit JSON output (shorthand for -format=json)")
+	format := flag.String("format", "text", "output format: text, json, or sarif")
+	flag.Parse()
+
+	if *jsonOutput && *format == "text" {
+		*format = "json"
+	}
+
+	violated, err := check(*configPath, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "depcheck:", err)
+		os.Exit(2)
+	}
+	if violated {
+		os.Exit(1)
+	}
+}
+
+func check(configPath, format string) (bool, error) {
+	config, configRoot, err := depcheck.LoadConfig(configPath)
+	if err != nil {
+		return false, fmt.Errorf("could not load config file (looked for %q): %w", configPath, err)
+	}
+	if len(config.Entrypoints) == 0 {
+		return false, fmt.Errorf("%s declares no entrypoints: add an `entrypoints:` list of package patterns to check", configPath)
+	}
+
+	checker, err := depcheck.NewChecker(config, configRoot)
+	if err != nil {
+		return false, err
+	}
+
+	pkgs, err := packages.Load(&packages.Config{
+		Dir: configRoot,
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+	}, config.Entrypoints...)
+	if err != nil {
+		return false, fmt.Errorf("failed to load entrypoints: %w", err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return false, fmt.Errorf("encountered errors loading entrypoints %v", config.Entrypoints)
+	}
+
+	findings := collectFindings(checker, pkgs)
+
+	if format == "text" {
+		printText(findings)
+		return len(findings) > 0, nil
+	}
+
+	reporter, err := depcheck.NewReporter(format, os.Stdout, checker.Rules())
+	if err != nil {
+		return false, err
+	}
+	for _, f := range findings {
+		if err := reporter.Report(f); err != nil {
+			return false, fmt.Errorf("failed to write %s output: %w", format, err)
+		}
+	}
+	if err := reporter.Flush(); err != nil {
+		return false, fmt.Errorf("failed to write %s output: %w", format, err)
+	}
+
+	return len(findings) > 0, nil
+}
+
+// collectFindings walks the whole package graph reachable from pkgs,
+// checking every import against the checker's rules.
+func collectFindings(checker *depcheck.Checker, pkgs []*packages.Package) []depcheck.Finding {
+	var findings []depcheck.Finding
+	seen := make(map[string]bool)
+	opts := depcheck.CheckOptions{}
+
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if seen[pkg.PkgPath] || pkg.Types == nil {
+			return
+		}
+		seen[pkg.PkgPath] = true
+
+		directImports := make(map[string]*types.Package, len(pkg.Types.Imports()))
+		for _, imp := range pkg.Types.Imports() {
+			directImports[imp.Path()] = imp
+		}
+		resolvePkg := func(path string) *types.Package { return directImports[path] }
+
+		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.Position(file.Pos()).Filename
+
+			for _, spec := range file.Imports {
+				path, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					continue
+				}
+				allowRuleID, hasAllow := depcheck.ParseAllowComment(spec)
+
+				for _, v := range checker.CheckImport(pkg.PkgPath, filename, path, allowRuleID, hasAllow, opts, resolvePkg) {
+					findings = append(findings, depcheck.NewFinding(v, filename, pkg.Fset.Position(spec.Pos()).Line))
+				}
+			}
+		}
+	})
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].FromPkg != findings[j].FromPkg {
+			return findings[i].FromPkg < findings[j].FromPkg
+		}
+		return findings[i].ImportPath < findings[j].ImportPath
+	})
+
+	return findings
+}
+
+// printText renders findings grouped by the package they were found in, as
+// `from-package: [violations...]`. This grouping is specific enough to the
+// CLI's interactive use that it lives here rather than in depcheck.Reporter.
+func printText(findings []depcheck.Finding) {
+	if len(findings) == 0 {
+		fmt.Println("depcheck: no violations found")
+		return
+	}
+
+	var currentPkg string
+	for _, f := range findings {
+		if f.FromPkg != currentPkg {
+			currentPkg = f.FromPkg
+			fmt.Printf("%s:\n", currentPkg)
+		}
+		fmt.Printf("  %s:%d: %s\n", f.File, f.Line, f.Message())
+	}
+}
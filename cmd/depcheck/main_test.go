@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeModule lays out a minimal Go module under dir with an entrypoint
+// package "app" that imports "internal/forbidden", so depcheck.yml's
+// `entrypoints: [./app]` has a real import graph to walk.
+func writeModule(t *testing.T, dir string) {
+	t.Helper()
+
+	files := map[string]string{
+		"go.mod": "module example.com/checktest\n\ngo 1.21\n",
+		"app/main.go": `package main
+
+import (
+	"fmt"
+
+	_ "example.com/checktest/internal/forbidden"
+)
+
+func main() { fmt.Println("ok") }
+`,
+		"internal/forbidden/forbidden.go": `package forbidden
+
+const Name = "forbidden"
+`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckReportsDirectViolation(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir)
+
+	configPath := filepath.Join(dir, "depcheck.yml")
+	config := `
+entrypoints:
+  - ./app
+rules:
+  - from: 'example.com/checktest/app$'
+    to:
+      - 'example.com/checktest/internal/forbidden$'
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DEPCHECK_CONFIG", configPath)
+
+	violated, err := check(configPath, "text")
+	if err != nil {
+		t.Fatalf("check() returned an error: %v", err)
+	}
+	if !violated {
+		t.Fatal("check() = false, want true (app imports internal/forbidden)")
+	}
+}
+
+func TestCheckJSONOutputRoundTrips(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir)
+
+	configPath := filepath.Join(dir, "depcheck.yml")
+	config := `
+entrypoints:
+  - ./app
+rules:
+  - from: 'example.com/checktest/app$'
+    to:
+      - 'example.com/checktest/internal/forbidden$'
+`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DEPCHECK_CONFIG", configPath)
+
+	stdout, err := os.CreateTemp(dir, "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	restore := os.Stdout
+	os.Stdout = stdout
+	violated, checkErr := check(configPath, "json")
+	os.Stdout = restore
+	if checkErr != nil {
+		t.Fatalf("check() returned an error: %v", checkErr)
+	}
+	if !violated {
+		t.Fatal("check() = false, want true")
+	}
+
+	data, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var findings []jsonFinding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		t.Fatalf("stdout is not valid JSON: %v\noutput:\n%s", err, data)
+	}
+	if len(findings) != 1 {
+		t.Fatalf("decoded %d findings, want 1", len(findings))
+	}
+}
+
+// jsonFinding mirrors the unexported shape depcheck.NewReporter's JSON format
+// writes, just enough of it to assert the CLI's JSON output round-trips.
+type jsonFinding struct {
+	RuleID     string `json:"ruleId"`
+	ImportPath string `json:"importPath"`
+}
+
+// TestCheckOutputToPipeDoesNotError covers the CLI's normal CI invocation,
+// `depcheck --format=json | ...` / `--format=sarif | ...`, where stdout is a
+// pipe rather than a regular file. os.CreateTemp in TestCheckJSONOutputRoundTrips
+// is seekable and so doesn't exercise this path.
+func TestCheckOutputToPipeDoesNotError(t *testing.T) {
+	for _, format := range []string{"json", "sarif"} {
+		t.Run(format, func(t *testing.T) {
+			dir := t.TempDir()
+			writeModule(t, dir)
+
+			configPath := filepath.Join(dir, "depcheck.yml")
+			config := `
+entrypoints:
+  - ./app
+rules:
+  - from: 'example.com/checktest/app$'
+    to:
+      - 'example.com/checktest/internal/forbidden$'
+`
+			if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			t.Setenv("DEPCHECK_CONFIG", configPath)
+
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			restore := os.Stdout
+			os.Stdout = w
+			violated, checkErr := check(configPath, format)
+			os.Stdout = restore
+			w.Close()
+			if checkErr != nil {
+				t.Fatalf("check() with stdout piped returned an error: %v", checkErr)
+			}
+			if !violated {
+				t.Fatal("check() = false, want true")
+			}
+
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) == 0 {
+				t.Fatal("check() wrote nothing to the pipe")
+			}
+		})
+	}
+}
@@ -1,10 +1,11 @@
 package depcheck
 
 import (
+	"flag"
 	"fmt"
 	"go/ast"
+	"go/types"
 	"golang.org/x/tools/go/analysis"
-	"gopkg.in/yaml.v3"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -15,10 +16,27 @@ import (
 
 const doc = "depcheck checks package dependency rules defined in YAML"
 
+// Severity levels a rule can be resolved to: the rule's config-level
+// `severity:` field, overridden by the `-depcheck.severity` flag when set.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityOff     = "off"
+)
+
+// Pattern syntaxes supported for IgnorePatterns / rule-level ignorePatterns.
+const (
+	patternSyntaxRegexp    = "regexp"
+	patternSyntaxGitignore = "gitignore"
+)
+
 // Config represents the structure of the YAML configuration file
 type Config struct {
 	IgnorePatterns []string         `yaml:"ignorePatterns"` // Global patterns to ignore
 	Rules          []DependencyRule `yaml:"rules"`
+	PatternSyntax  string           `yaml:"patternSyntax"` // regexp (back-compat) or gitignore; empty preserves the regexp behavior of configs written before this field existed
+	MaxDepth       int              `yaml:"maxDepth"`       // Maximum import-chain length a `mode: transitive` rule will walk; 0 means unlimited
+	Entrypoints    []string         `yaml:"entrypoints"`    // Package patterns the depcheck CLI (cmd/depcheck) loads the whole program from; unused by the go vet analyzer
 }
 
 // DependencyRule represents a single dependency rule
@@ -27,7 +45,9 @@ type DependencyRule struct {
 	To                  []string `yaml:"to"`                  // Target package patterns (multiple allowed)
 	AllowedDependencies []string `yaml:"allowedDependencies"` // Patterns for allowed dependencies
 	IgnorePatterns      []string `yaml:"ignorePatterns"`      // Patterns for files to exclude from analysis
-
+	ID                  string   `yaml:"id"`                  // Stable identifier referenced by inline allow comments and CLI flags
+	Severity            string   `yaml:"severity"`            // error (default), warning, or off
+	Mode                string   `yaml:"mode"`                // direct (default): only the package's own imports; transitive: walk the whole import graph
 }
 
 // compiledRule holds the compiled regular expressions for rule matching
@@ -35,85 +55,98 @@ type compiledRule struct {
 	from                *regexp.Regexp
 	to                  []*regexp.Regexp
 	allowedDependencies []*regexp.Regexp
-	ignorePatterns      []*regexp.Regexp
+	ignorePatterns      ignoreMatcher
+	id                  string
+	severity            string
+	mode                string
 }
 
+var depcheckFlags = flag.NewFlagSet("depcheck", flag.ContinueOnError)
+
+var (
+	flagDisable    = depcheckFlags.String("depcheck.disable", "", "comma-separated rule ids to disable")
+	flagEnableOnly = depcheckFlags.String("depcheck.enable-only", "", "comma-separated rule ids; when set, only these rules run")
+	flagSeverity   = depcheckFlags.String("depcheck.severity", "", "override every rule's severity (error|warning|off)")
+	flagOutput     = depcheckFlags.String("depcheck.output", "", "also write structured findings in this format (json|sarif) to -depcheck.output-file")
+	flagOutputFile = depcheckFlags.String("depcheck.output-file", "", "file to write -depcheck.output to (defaults to $DEPCHECK_OUTPUT_FILE)")
+)
+
 var Analyzer = &analysis.Analyzer{
 	Name:     "depcheck",
 	Doc:      doc,
 	Run:      run,
 	Requires: []*analysis.Analyzer{},
+	Flags:    *depcheckFlags,
+}
+
+// analyzerState bundles the pieces prepare() builds once per process: the
+// compiled Checker, and the optional structured-output Reporter driven by
+// DEPCHECK_OUTPUT.
+type analyzerState struct {
+	checker  *Checker
+	reporter Reporter
 }
 
-// Variables to hold compiled rules and manage initialization state with mutex
+// prepareOnce builds analyzerState on the analyzer's first run and reuses it
+// for the remainder of the process. See checker.go for the Checker code
+// shared with the standalone depcheck CLI (cmd/depcheck), and reporter.go
+// for the Reporter shared between the two output paths.
 var (
-	compiledRules          []compiledRule
-	compiledIgnorePatterns []*regexp.Regexp
-	prepareOnce            = sync.OnceValue(prepare)
+	prepareOnce = sync.OnceValues(prepare)
+	reportMu    sync.Mutex
 )
 
-func prepare() error {
+func prepare() (analyzerState, error) {
 	configPath := "depcheck.yml"
 	if envPath := os.Getenv("DEPCHECK_CONFIG"); envPath != "" {
 		configPath = envPath
 	}
 
-	// Search for configuration file
-	foundPath, err := findConfigFile(configPath)
+	config, configRoot, err := LoadConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("could not find config file: %w", err)
+		return analyzerState{}, fmt.Errorf("could not load config file: %w", err)
 	}
 
-	// Read configuration file
-	data, err := os.ReadFile(foundPath)
+	checker, err := NewChecker(config, configRoot)
 	if err != nil {
-		return fmt.Errorf("warning: Could not read config file: %v\n", err)
-	}
-
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("warning: Could not parse config file: %v\n", err)
+		return analyzerState{}, err
 	}
 
-	// Compile global ignore patterns
-	compiledIgnorePatterns = make([]*regexp.Regexp, 0, len(config.IgnorePatterns))
-	for _, pattern := range config.IgnorePatterns {
-		compiled, err := regexp.Compile(pattern)
-		if err != nil {
-			return fmt.Errorf("invalid ignore pattern %q: %v", pattern, err)
-		}
-		compiledIgnorePatterns = append(compiledIgnorePatterns, compiled)
+	reporter, err := newEnvReporter(checker)
+	if err != nil {
+		return analyzerState{}, err
 	}
 
-	// Compile rules
-	compiledRules = make([]compiledRule, 0, len(config.Rules))
-	for _, rule := range config.Rules {
-		compiled := compiledRule{
-			from:                regexp.MustCompile(rule.From),
-			to:                  make([]*regexp.Regexp, 0, len(rule.To)),
-			allowedDependencies: make([]*regexp.Regexp, 0, len(rule.AllowedDependencies)),
-			ignorePatterns:      make([]*regexp.Regexp, 0, len(rule.IgnorePatterns)),
-		}
-
-		// Compile target patterns
-		for _, toPattern := range rule.To {
-			compiled.to = append(compiled.to, regexp.MustCompile(toPattern))
-		}
+	return analyzerState{checker: checker, reporter: reporter}, nil
+}
 
-		// Compile allowed dependency patterns
-		for _, allowedPattern := range rule.AllowedDependencies {
-			compiled.allowedDependencies = append(compiled.allowedDependencies, regexp.MustCompile(allowedPattern))
-		}
+// newEnvReporter builds the optional structured-output Reporter driven by
+// DEPCHECK_OUTPUT/-depcheck.output and DEPCHECK_OUTPUT_FILE/-depcheck.output-file.
+// It returns a nil Reporter, not an error, when structured output wasn't
+// requested, in which case go vet's own diagnostics remain the only output.
+func newEnvReporter(checker *Checker) (Reporter, error) {
+	format := *flagOutput
+	if format == "" {
+		format = os.Getenv("DEPCHECK_OUTPUT")
+	}
+	if format == "" {
+		return nil, nil
+	}
 
-		// Compile ignore patterns
-		for _, ignorePattern := range rule.IgnorePatterns {
-			compiled.ignorePatterns = append(compiled.ignorePatterns, regexp.MustCompile(ignorePattern))
-		}
+	path := *flagOutputFile
+	if path == "" {
+		path = os.Getenv("DEPCHECK_OUTPUT_FILE")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("-depcheck.output=%s requires -depcheck.output-file or $DEPCHECK_OUTPUT_FILE", format)
+	}
 
-		compiledRules = append(compiledRules, compiled)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not create output file %q: %w", path, err)
 	}
 
-	return nil
+	return NewReporter(format, file, checker.rules)
 }
 
 func findConfigFile(configPath string) (string, error) {
@@ -146,29 +179,38 @@ func findConfigFile(configPath string) (string, error) {
 	return "", fmt.Errorf("config file %s not found in any parent directory", configPath)
 }
 
-// hasExceptionComment checks if an import statement has an exception comment
-func hasExceptionComment(spec *ast.ImportSpec) bool {
-	if strings.HasPrefix(spec.Comment.Text(), "depcheck:allow") {
-		return true
+// parseAllowComment parses a `depcheck:allow` or `depcheck:allow=<rule-id>` exception
+// comment attached to an import spec. ok reports whether an allow directive was
+// present at all; ruleID is empty when the directive allows every rule on this import.
+func parseAllowComment(spec *ast.ImportSpec) (ruleID string, ok bool) {
+	text := strings.TrimSpace(spec.Comment.Text())
+	if !strings.HasPrefix(text, "depcheck:allow") {
+		return "", false
 	}
-	return false
-}
 
-// shouldIgnore checks if a file should be ignored based on ignore patterns
-func shouldIgnoreFile(filename string, globalPatterns []*regexp.Regexp, rulePatterns []*regexp.Regexp) bool {
-	for _, pattern := range globalPatterns {
-		if pattern.MatchString(filename) {
-			return true
-		}
+	rest := strings.TrimSpace(strings.TrimPrefix(text, "depcheck:allow"))
+	if rest == "" {
+		return "", true
 	}
-
-	for _, pattern := range rulePatterns {
-		if pattern.MatchString(filename) {
-			return true
-		}
+	if strings.HasPrefix(rest, "=") {
+		return strings.TrimSpace(strings.TrimPrefix(rest, "=")), true
 	}
 
-	return false
+	return "", true
+}
+
+// ParseAllowComment is the exported form of parseAllowComment, for callers
+// outside this package (such as cmd/depcheck) that walk their own ASTs.
+func ParseAllowComment(spec *ast.ImportSpec) (ruleID string, ok bool) {
+	return parseAllowComment(spec)
+}
+
+// shouldIgnoreFile checks if a file should be ignored based on ignore patterns
+func shouldIgnoreFile(filename string, globalPatterns ignoreMatcher, rulePatterns ignoreMatcher) bool {
+	if globalPatterns.MatchFile(filename) {
+		return true
+	}
+	return rulePatterns.MatchFile(filename)
 }
 
 // isAllowed checks if an import path is allowed as an exception
@@ -181,18 +223,92 @@ func isAllowed(rule compiledRule, importPath string) bool {
 	return false
 }
 
+// parseIDSet splits a comma-separated list of rule ids from a flag value into a
+// lookup set. An empty csv yields a nil set, which callers treat as "unset".
+func parseIDSet(csv string) map[string]bool {
+	if csv == "" {
+		return nil
+	}
+
+	ids := make(map[string]bool)
+	for _, id := range strings.Split(csv, ",") {
+		id = strings.TrimSpace(id)
+		if id != "" {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// resolveSeverity applies the `-depcheck.severity` flag override, if any, over
+// a rule's configured severity. override must already be normalized and
+// validated by normalizeSeverityOverride.
+func resolveSeverity(ruleSeverity, override string) string {
+	if override != "" {
+		return override
+	}
+	return ruleSeverity
+}
+
+// normalizeSeverityOverride validates the raw `-depcheck.severity` flag value
+// and normalizes it to one of severityError/severityWarning/severityOff,
+// mirroring the validation NewChecker already applies to a rule's configured
+// `severity:`. An empty raw value means "no override" and is returned as-is.
+// "warn" is accepted as a synonym for "warning".
+func normalizeSeverityOverride(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	severity := raw
+	if severity == "warn" {
+		severity = severityWarning
+	}
+
+	if severity != severityError && severity != severityWarning && severity != severityOff {
+		return "", fmt.Errorf("invalid -depcheck.severity %q: must be %q, %q, %q, or %q", raw, severityError, severityWarning, severityOff, "warn")
+	}
+	return severity, nil
+}
+
+// allowComment builds the `// depcheck:allow[=id]` suggested-fix text for a rule.
+func allowComment(ruleID string) string {
+	if ruleID == "" {
+		return " // depcheck:allow"
+	}
+	return fmt.Sprintf(" // depcheck:allow=%s", ruleID)
+}
+
 func run(pass *analysis.Pass) (any, error) {
 	// Execute initialization only once
-	err := prepareOnce()
+	state, err := prepareOnce()
+	if err != nil {
+		return nil, err
+	}
+	checker := state.checker
+
+	severityOverride, err := normalizeSeverityOverride(strings.TrimSpace(*flagSeverity))
 	if err != nil {
 		return nil, err
 	}
 
+	opts := CheckOptions{
+		Disabled:         parseIDSet(*flagDisable),
+		EnableOnly:       parseIDSet(*flagEnableOnly),
+		SeverityOverride: severityOverride,
+	}
+
 	pkgpath := pass.Pkg.Path()
 
+	directImports := make(map[string]*types.Package, len(pass.Pkg.Imports()))
+	for _, imp := range pass.Pkg.Imports() {
+		directImports[imp.Path()] = imp
+	}
+	resolvePkg := func(path string) *types.Package { return directImports[path] }
+
 	for _, file := range pass.Files {
 		pos := pass.Fset.Position(file.Pos())
-		filename := filepath.Base(pos.Filename)
+		filename := pos.Filename
 
 		for _, spec := range file.Imports {
 			path, err := strconv.Unquote(spec.Path.Value)
@@ -200,31 +316,39 @@ func run(pass *analysis.Pass) (any, error) {
 				continue
 			}
 
-			// Check for exception comments
-			if hasExceptionComment(spec) {
-				continue
-			}
-
-			// Check against each rule
-			for _, rule := range compiledRules {
-				if !rule.from.MatchString(pkgpath) {
-					continue
-				}
-
-				// Skip files matching ignore patterns
-				if shouldIgnoreFile(filename, compiledIgnorePatterns, rule.ignorePatterns) {
-					continue
-				}
-
-				// Check for allowed dependencies
-				if isAllowed(rule, path) {
-					continue
-				}
+			allowRuleID, hasAllow := parseAllowComment(spec)
+
+			for _, violation := range checker.CheckImport(pkgpath, filename, path, allowRuleID, hasAllow, opts, resolvePkg) {
+				pass.Report(analysis.Diagnostic{
+					Pos:     spec.Pos(),
+					Message: violation.Message(),
+					SuggestedFixes: []analysis.SuggestedFix{
+						{
+							Message: "Suppress with a depcheck:allow comment",
+							TextEdits: []analysis.TextEdit{
+								{
+									Pos:     spec.End(),
+									End:     spec.End(),
+									NewText: []byte(allowComment(violation.RuleID)),
+								},
+							},
+						},
+					},
+				})
+
+				if state.reporter != nil {
+					line := pass.Fset.Position(spec.Pos()).Line
+					finding := NewFinding(violation, filename, line)
+
+					reportMu.Lock()
+					reportErr := state.reporter.Report(finding)
+					if reportErr == nil {
+						reportErr = state.reporter.Flush()
+					}
+					reportMu.Unlock()
 
-				// Check for dependency violations
-				for _, toPattern := range rule.to {
-					if toPattern.MatchString(path) {
-						pass.Reportf(spec.Pos(), "invalid dependency: %s", path)
+					if reportErr != nil {
+						return nil, fmt.Errorf("could not write structured output: %w", reportErr)
 					}
 				}
 			}
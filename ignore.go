@@ -0,0 +1,213 @@
+package depcheck
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreMatcher decides whether a given file path should be excluded from
+// analysis. filename is the path as recorded by the parser (may be absolute
+// or relative, depending on how the package was loaded).
+type ignoreMatcher interface {
+	MatchFile(filename string) bool
+}
+
+// regexpMatcher preserves the original behavior: any pattern in the list
+// matching the file's base name excludes it.
+type regexpMatcher struct {
+	patterns []*regexp.Regexp
+}
+
+func (m *regexpMatcher) MatchFile(filename string) bool {
+	base := filepath.Base(filename)
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(base) {
+			return true
+		}
+	}
+	return false
+}
+
+// segment is one "/"-delimited piece of a gitignore pattern: either a literal
+// component glob (possibly containing `*`/`?`) or the special `**` wildcard,
+// which matches zero or more path components.
+type segment struct {
+	doubleStar bool
+	re         *regexp.Regexp
+}
+
+// ignorePattern is a single parsed line of a gitignore-style pattern file.
+type ignorePattern struct {
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	segments []segment
+}
+
+// gitignoreMatcher evaluates an ordered list of gitignore-style patterns.
+// Per gitignore semantics, the polarity of the last matching pattern wins.
+type gitignoreMatcher struct {
+	root     string
+	patterns []ignorePattern
+}
+
+func newGitignoreMatcher(root string, lines []string) *gitignoreMatcher {
+	m := &gitignoreMatcher{root: root}
+	for _, line := range lines {
+		if pattern, ok := parseIgnorePattern(line); ok {
+			m.patterns = append(m.patterns, pattern)
+		}
+	}
+	return m
+}
+
+func (m *gitignoreMatcher) MatchFile(filename string) bool {
+	rel := relativeToRoot(m.root, filename)
+	components := strings.Split(rel, "/")
+
+	ignored := false
+	for _, pattern := range m.patterns {
+		if matchSegments(pattern.segments, components, pattern.dirOnly) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+// relativeToRoot reformats filename relative to root using forward slashes,
+// falling back to filename itself (slash-normalized) when it isn't under root.
+func relativeToRoot(root, filename string) string {
+	rel := filepath.ToSlash(filename)
+	if r, err := filepath.Rel(root, filename); err == nil && !strings.HasPrefix(r, "..") {
+		rel = filepath.ToSlash(r)
+	}
+	return strings.TrimPrefix(rel, "/")
+}
+
+// parseIgnorePattern parses a single line of a .gitignore/.dockerignore/.helmignore-
+// style file. It returns ok=false for blank lines and comments.
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, "\r\n")
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return ignorePattern{}, false
+	}
+
+	var pattern ignorePattern
+	if strings.HasPrefix(line, "!") {
+		pattern.negate = true
+		line = line[1:]
+	}
+	if strings.HasPrefix(line, "/") {
+		pattern.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		pattern.anchored = true
+	}
+
+	parts := strings.Split(line, "/")
+	segments := make([]segment, 0, len(parts)+1)
+	for _, part := range parts {
+		if part == "**" {
+			segments = append(segments, segment{doubleStar: true})
+			continue
+		}
+		segments = append(segments, segment{re: compileComponentGlob(part)})
+	}
+	if !pattern.anchored {
+		segments = append([]segment{{doubleStar: true}}, segments...)
+	}
+	pattern.segments = segments
+
+	return pattern, true
+}
+
+// compileComponentGlob translates a single path-component glob (using `*` and
+// `?`, as in shell/gitignore patterns) into an anchored regular expression.
+func compileComponentGlob(part string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range part {
+		switch r {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// matchSegments walks a pattern's segments against a file path's components,
+// backtracking through `**` wildcards as needed. dirOnly requires at least
+// one path component to remain once the pattern is fully consumed, since
+// MatchFile is only ever called with file paths: a dirOnly pattern (one
+// written with a trailing `/`) must match an ancestor directory, never the
+// file itself.
+func matchSegments(segments []segment, components []string, dirOnly bool) bool {
+	if len(segments) == 0 {
+		// The pattern matched a full path prefix. As in gitignore, matching a
+		// directory also matches everything beneath it.
+		if dirOnly && len(components) == 0 {
+			return false
+		}
+		return true
+	}
+
+	head := segments[0]
+	if head.doubleStar {
+		if matchSegments(segments[1:], components, dirOnly) {
+			return true
+		}
+		if len(components) == 0 {
+			return false
+		}
+		return matchSegments(segments, components[1:], dirOnly)
+	}
+
+	if len(components) == 0 || !head.re.MatchString(components[0]) {
+		return false
+	}
+	return matchSegments(segments[1:], components[1:], dirOnly)
+}
+
+// loadDepcheckIgnoreFile reads additional gitignore-style patterns from a
+// .depcheckignore file next to the resolved config file, if one exists.
+func loadDepcheckIgnoreFile(root string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, ".depcheckignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return strings.Split(string(data), "\n"), nil
+}
+
+// buildMatcher compiles lines into an ignoreMatcher according to syntax,
+// which is either "regexp" (back-compat) or "gitignore".
+func buildMatcher(syntax, root string, lines []string) (ignoreMatcher, error) {
+	if syntax == patternSyntaxGitignore {
+		return newGitignoreMatcher(root, lines), nil
+	}
+
+	patterns := make([]*regexp.Regexp, 0, len(lines))
+	for _, line := range lines {
+		compiled, err := regexp.Compile(line)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, compiled)
+	}
+	return &regexpMatcher{patterns: patterns}, nil
+}
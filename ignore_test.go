@@ -0,0 +1,58 @@
+package depcheck
+
+import "testing"
+
+func TestGitignoreMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		filename string
+		want     bool
+	}{
+		{
+			name:     "simple basename pattern matches anywhere",
+			lines:    []string{"*_mock.go"},
+			filename: "/src/example/foo_mock.go",
+			want:     true,
+		},
+		{
+			name:     "double star matches nested directories",
+			lines:    []string{"vendor/**/testdata"},
+			filename: "/root/vendor/a/b/testdata/fixture.go",
+			want:     true,
+		},
+		{
+			name:     "anchored pattern only matches from root",
+			lines:    []string{"/generated.go"},
+			filename: "/root/sub/generated.go",
+			want:     false,
+		},
+		{
+			name:     "negation re-includes a previously ignored file",
+			lines:    []string{"*.go", "!keep.go"},
+			filename: "/root/keep.go",
+			want:     false,
+		},
+		{
+			name:     "dir-only pattern matches files beneath the directory",
+			lines:    []string{"testdata/"},
+			filename: "/root/testdata/fixture.go",
+			want:     true,
+		},
+		{
+			name:     "dir-only pattern does not match a file sharing its name",
+			lines:    []string{"testdata/"},
+			filename: "/root/testdata",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newGitignoreMatcher("/root", tt.lines)
+			if got := m.MatchFile(tt.filename); got != tt.want {
+				t.Errorf("MatchFile(%q) = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
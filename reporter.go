@@ -0,0 +1,298 @@
+package depcheck
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"syscall"
+)
+
+// Finding is the structured form of a single rule violation, enriched with
+// the source location that produced it and a stable fingerprint so CI
+// systems (GitHub code scanning, GitLab, Sonar) can deduplicate findings
+// across runs.
+type Finding struct {
+	Violation
+	File        string
+	Line        int
+	Fingerprint string
+}
+
+// NewFinding builds a Finding from a Violation and the source location that
+// produced it.
+func NewFinding(v Violation, file string, line int) Finding {
+	return Finding{
+		Violation:   v,
+		File:        file,
+		Line:        line,
+		Fingerprint: fingerprint(v.RuleID, v.FromPkg, v.ImportPath),
+	}
+}
+
+// fingerprint derives a stable identifier for a (rule, from-package,
+// import-path) triple, independent of message wording or file/line, so a CI
+// system can recognize the "same" finding across commits.
+func fingerprint(ruleID, fromPkg, importPath string) string {
+	sum := sha256.Sum256([]byte(ruleID + "|" + fromPkg + "|" + importPath))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Reporter receives findings as they're discovered and decides how to
+// surface them. Flush writes out any buffered output (a JSON array, a SARIF
+// log) without closing the underlying writer, which callers own. Flush may be
+// called more than once as more findings accumulate (the go vet analyzer
+// flushes after every violation so output exists even if the process is
+// killed mid-run); each call must leave w holding exactly the findings seen
+// so far, not an append of the previous call's output.
+type Reporter interface {
+	Report(Finding) error
+	Flush() error
+}
+
+// seeker is the subset of *os.File that a buffered Reporter needs to rewrite
+// its output from scratch on every Flush, so repeated flushes of a growing
+// findings slice don't concatenate multiple encodings into one file. w is
+// asserted against this interface rather than requiring it outright, since
+// NewReporter accepts any io.Writer, and a writer that is only ever flushed
+// once (e.g. os.Stdout in cmd/depcheck) needs no reset.
+type seeker interface {
+	io.Seeker
+	Truncate(int64) error
+}
+
+// resetForFlush rewinds and truncates w before re-encoding the full
+// accumulated findings, if w supports it. Writers that don't implement
+// seeker are left alone.
+// The rewind is best-effort: w satisfies seeker statically whenever it's an
+// *os.File, but a pipe (e.g. cmd/depcheck's os.Stdout piped into another
+// command, the normal CI invocation) fails Seek with ESPIPE at runtime. That
+// failure is expected and not an error, since a writer that can't seek is by
+// definition only ever flushed once, which needs no reset.
+func resetForFlush(w io.Writer) error {
+	s, ok := w.(seeker)
+	if !ok {
+		return nil
+	}
+	if _, err := s.Seek(0, io.SeekStart); err != nil {
+		if errors.Is(err, syscall.ESPIPE) {
+			return nil
+		}
+		return err
+	}
+	if err := s.Truncate(0); err != nil {
+		if errors.Is(err, syscall.ESPIPE) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// NewReporter builds a Reporter for the given format ("text", "json", or
+// "sarif"), writing to w. rules supplies the compiled rule set so SARIF
+// output can describe each rule under runs[].tool.driver.rules.
+func NewReporter(format string, w io.Writer, rules []compiledRule) (Reporter, error) {
+	switch format {
+	case outputFormatText:
+		return &textReporter{w: w}, nil
+	case outputFormatJSON:
+		return &jsonReporter{w: w}, nil
+	case outputFormatSARIF:
+		return &sarifReporter{w: w, rules: rules}, nil
+	default:
+		return nil, fmt.Errorf("invalid output format %q: must be %q, %q, or %q", format, outputFormatText, outputFormatJSON, outputFormatSARIF)
+	}
+}
+
+// Output formats accepted by DEPCHECK_OUTPUT / -depcheck.output and by the
+// depcheck CLI's -format flag.
+const (
+	outputFormatText  = "text"
+	outputFormatJSON  = "json"
+	outputFormatSARIF = "sarif"
+)
+
+type textReporter struct {
+	w io.Writer
+}
+
+func (r *textReporter) Report(f Finding) error {
+	_, err := fmt.Fprintf(r.w, "%s:%d: [%s] %s\n", f.File, f.Line, f.Fingerprint, f.Message())
+	return err
+}
+
+func (r *textReporter) Flush() error { return nil }
+
+type jsonFinding struct {
+	RuleID      string   `json:"ruleId,omitempty"`
+	FromPackage string   `json:"fromPackage"`
+	ImportPath  string   `json:"importPath"`
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Severity    string   `json:"severity"`
+	Chain       []string `json:"chain,omitempty"`
+	Message     string   `json:"message"`
+	Fingerprint string   `json:"fingerprint"`
+}
+
+type jsonReporter struct {
+	w        io.Writer
+	findings []jsonFinding
+}
+
+func (r *jsonReporter) Report(f Finding) error {
+	r.findings = append(r.findings, jsonFinding{
+		RuleID:      f.RuleID,
+		FromPackage: f.FromPkg,
+		ImportPath:  f.ImportPath,
+		File:        f.File,
+		Line:        f.Line,
+		Severity:    f.Severity,
+		Chain:       f.Chain,
+		Message:     f.Message(),
+		Fingerprint: f.Fingerprint,
+	})
+	return nil
+}
+
+func (r *jsonReporter) Flush() error {
+	if err := resetForFlush(r.w); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.findings)
+}
+
+// SARIF 2.1.0 document structure, enough for GitHub/GitLab/Sonar code
+// scanning to render a finding at a file:line with a message, a rule
+// description, and a fingerprint for cross-run deduplication.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string               `json:"name"`
+	Rules []sarifRuleDescriptor `json:"rules,omitempty"`
+}
+
+type sarifRuleDescriptor struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID       string            `json:"ruleId,omitempty"`
+	Level        string            `json:"level"`
+	Message      sarifMessage      `json:"message"`
+	Locations    []sarifLocation   `json:"locations"`
+	Fingerprints map[string]string `json:"fingerprints,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifReporter struct {
+	w       io.Writer
+	rules   []compiledRule
+	results []sarifResult
+}
+
+func (r *sarifReporter) Report(f Finding) error {
+	level := "error"
+	if f.Severity == severityWarning {
+		level = "warning"
+	}
+
+	r.results = append(r.results, sarifResult{
+		RuleID:  f.RuleID,
+		Level:   level,
+		Message: sarifMessage{Text: f.Message()},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.File},
+				Region:           sarifRegion{StartLine: f.Line},
+			},
+		}},
+		Fingerprints: map[string]string{"depcheck/v1": f.Fingerprint},
+	})
+	return nil
+}
+
+func (r *sarifReporter) Flush() error {
+	if err := resetForFlush(r.w); err != nil {
+		return err
+	}
+
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:  "depcheck",
+				Rules: sarifRuleDescriptors(r.rules),
+			}},
+			Results: r.results,
+		}},
+	}
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+func sarifRuleDescriptors(rules []compiledRule) []sarifRuleDescriptor {
+	descriptors := make([]sarifRuleDescriptor, 0, len(rules))
+	for i, rule := range rules {
+		id := rule.id
+		if id == "" {
+			id = fmt.Sprintf("#%d", i)
+		}
+
+		to := make([]string, 0, len(rule.to))
+		for _, pattern := range rule.to {
+			to = append(to, pattern.String())
+		}
+
+		descriptors = append(descriptors, sarifRuleDescriptor{
+			ID: id,
+			ShortDescription: sarifMessage{
+				Text: fmt.Sprintf("%s must not depend on %s", rule.from.String(), strings.Join(to, ", ")),
+			},
+		})
+	}
+	return descriptors
+}
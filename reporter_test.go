@@ -0,0 +1,131 @@
+package depcheck
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONReporterFlushIsIdempotentAcrossMultipleCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.json")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reporter, err := NewReporter(outputFormatJSON, file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations := []Violation{
+		{RuleID: "a", FromPkg: "x", ImportPath: "y", Severity: severityError},
+		{RuleID: "b", FromPkg: "x", ImportPath: "z", Severity: severityWarning},
+	}
+
+	// Mirror depcheck.go's run(): Report+Flush after every single finding,
+	// as the go vet analyzer does so output exists even if killed mid-run.
+	for i, v := range violations {
+		if err := reporter.Report(NewFinding(v, "x.go", i+1)); err != nil {
+			t.Fatal(err)
+		}
+		if err := reporter.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []jsonFinding
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("output after %d flushes is not valid JSON: %v\noutput:\n%s", len(violations), err, data)
+	}
+	if len(decoded) != len(violations) {
+		t.Fatalf("decoded %d findings, want %d", len(decoded), len(violations))
+	}
+}
+
+func TestSARIFReporterFlushIsIdempotentAcrossMultipleCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.sarif")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reporter, err := NewReporter(outputFormatSARIF, file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations := []Violation{
+		{RuleID: "a", FromPkg: "x", ImportPath: "y", Severity: severityError},
+		{RuleID: "b", FromPkg: "x", ImportPath: "z", Severity: severityWarning},
+	}
+
+	for i, v := range violations {
+		if err := reporter.Report(NewFinding(v, "x.go", i+1)); err != nil {
+			t.Fatal(err)
+		}
+		if err := reporter.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded sarifLog
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("output after %d flushes is not valid JSON: %v\noutput:\n%s", len(violations), err, data)
+	}
+	if len(decoded.Runs) != 1 || len(decoded.Runs[0].Results) != len(violations) {
+		t.Fatalf("decoded %+v, want %d results", decoded, len(violations))
+	}
+}
+
+// TestReporterFlushToPipeDoesNotError covers cmd/depcheck's normal CI
+// invocation, `depcheck --json | ...`, where stdout is a pipe rather than a
+// regular file: *os.File satisfies the seeker interface statically, but
+// Seek on a pipe fails with ESPIPE at runtime, and Flush is only ever called
+// once there, so the reset must be skipped rather than erroring.
+func TestReporterFlushToPipeDoesNotError(t *testing.T) {
+	for _, format := range []string{outputFormatJSON, outputFormatSARIF} {
+		t.Run(format, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			reporter, err := NewReporter(format, w, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := reporter.Report(NewFinding(Violation{RuleID: "a", FromPkg: "x", ImportPath: "y", Severity: severityError}, "x.go", 1)); err != nil {
+				t.Fatal(err)
+			}
+			if err := reporter.Flush(); err != nil {
+				t.Fatalf("Flush() to a pipe returned an error: %v", err)
+			}
+			w.Close()
+
+			data, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) == 0 {
+				t.Fatal("Flush() wrote nothing to the pipe")
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package depcheck
+
+import "testing"
+
+func TestNormalizeSeverityOverride(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty means no override", raw: "", want: ""},
+		{name: "error passes through", raw: "error", want: severityError},
+		{name: "warning passes through", raw: "warning", want: severityWarning},
+		{name: "off passes through", raw: "off", want: severityOff},
+		{name: "warn is a synonym for warning", raw: "warn", want: severityWarning},
+		{name: "unknown value is rejected", raw: "critical", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeSeverityOverride(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("normalizeSeverityOverride(%q) = %q, nil; want an error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeSeverityOverride(%q) returned unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Fatalf("normalizeSeverityOverride(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package depcheck
+
+import (
+	"fmt"
+	"go/types"
+	"regexp"
+	"sync"
+)
+
+// Rule modes: "direct" (the default) only inspects a package's own imports;
+// "transitive" walks the full import graph looking for a forbidden package
+// reachable through any number of intermediate packages.
+const (
+	ruleModeDirect     = "direct"
+	ruleModeTransitive = "transitive"
+)
+
+// transitiveCache memoizes, per (package, rule) pair, the shortest chain of
+// import paths leading from that package to one matching the rule's `to`
+// patterns. It's scoped to a single Checker, since the rule set (and thus
+// the meaning of a ruleKey) differs between Checkers.
+type transitiveCache struct {
+	results sync.Map // map[transitiveCacheKey][]string
+}
+
+type transitiveCacheKey struct {
+	pkgPath string
+	ruleKey string
+}
+
+// ruleCacheKey returns a stable identifier for a rule to key the transitive
+// cache with. It's always keyed on the rule's position among rules, since
+// rule.id is user-supplied and not guaranteed unique across rules.
+func ruleCacheKey(index int, rule compiledRule) string {
+	return fmt.Sprintf("#%d", index)
+}
+
+// chain returns the shortest chain of import paths starting at path/pkg that
+// ends at a package matching rule's `to` patterns, or nil if none is
+// reachable. Packages matching rule.allowedDependencies are treated as
+// permitted, and the walk does not descend past them.
+func (c *transitiveCache) chain(ruleKey string, rule compiledRule, path string, pkg *types.Package) []string {
+	key := transitiveCacheKey{pkgPath: path, ruleKey: ruleKey}
+	if cached, ok := c.results.Load(key); ok {
+		return cached.([]string)
+	}
+
+	chain := walkTransitiveChain(rule, path, pkg)
+	c.results.Store(key, chain)
+	return chain
+}
+
+func walkTransitiveChain(rule compiledRule, startPath string, startPkg *types.Package) []string {
+	if isAllowed(rule, startPath) {
+		return nil
+	}
+	if matchesAny(rule.to, startPath) {
+		return []string{startPath}
+	}
+
+	type node struct {
+		pkg   *types.Package
+		chain []string
+	}
+
+	visited := map[string]bool{startPath: true}
+	queue := []node{{pkg: startPkg, chain: []string{startPath}}}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, imp := range cur.pkg.Imports() {
+			path := imp.Path()
+			if visited[path] {
+				continue
+			}
+			visited[path] = true
+
+			if isAllowed(rule, path) {
+				continue
+			}
+
+			chain := append(append([]string{}, cur.chain...), path)
+			if matchesAny(rule.to, path) {
+				return chain
+			}
+
+			queue = append(queue, node{pkg: imp, chain: chain})
+		}
+	}
+
+	return nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// withinMaxDepth reports whether a chain of the given length respects
+// maxDepth. A non-positive maxDepth means unlimited.
+func withinMaxDepth(chainLen, maxDepth int) bool {
+	return maxDepth <= 0 || chainLen <= maxDepth
+}
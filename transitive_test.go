@@ -0,0 +1,82 @@
+package depcheck
+
+import (
+	"go/types"
+	"regexp"
+	"testing"
+)
+
+// buildImportChain wires up A -> B -> C as *types.Package values with no
+// exported members, enough to drive walkTransitiveChain/transitiveCache.
+func buildImportChain() (a, b, c *types.Package) {
+	c = types.NewPackage("example.com/c", "c")
+	b = types.NewPackage("example.com/b", "b")
+	b.SetImports([]*types.Package{c})
+	a = types.NewPackage("example.com/a", "a")
+	a.SetImports([]*types.Package{b})
+	return a, b, c
+}
+
+func TestWalkTransitiveChainFindsIndirectDependency(t *testing.T) {
+	_, b, _ := buildImportChain()
+
+	rule := compiledRule{
+		to: []*regexp.Regexp{regexp.MustCompile("^example.com/c$")},
+	}
+
+	chain := walkTransitiveChain(rule, "example.com/b", b)
+	want := []string{"example.com/b", "example.com/c"}
+
+	if len(chain) != len(want) {
+		t.Fatalf("chain = %v, want %v", chain, want)
+	}
+	for i := range want {
+		if chain[i] != want[i] {
+			t.Fatalf("chain = %v, want %v", chain, want)
+		}
+	}
+}
+
+func TestWalkTransitiveChainStopsAtAllowedDependency(t *testing.T) {
+	_, b, _ := buildImportChain()
+
+	rule := compiledRule{
+		to:                  []*regexp.Regexp{regexp.MustCompile("^example.com/c$")},
+		allowedDependencies: []*regexp.Regexp{regexp.MustCompile("^example.com/c$")},
+	}
+
+	if chain := walkTransitiveChain(rule, "example.com/b", b); chain != nil {
+		t.Fatalf("chain = %v, want nil (allowed dependency should not be reported)", chain)
+	}
+}
+
+func TestRuleCacheKeyDoesNotCollideOnSharedID(t *testing.T) {
+	// Two rules sharing an explicit id must still get distinct cache keys;
+	// id is user-supplied and not guaranteed unique.
+	ruleOne := compiledRule{id: "shared"}
+	ruleTwo := compiledRule{id: "shared"}
+
+	keyOne := ruleCacheKey(0, ruleOne)
+	keyTwo := ruleCacheKey(1, ruleTwo)
+
+	if keyOne == keyTwo {
+		t.Fatalf("ruleCacheKey collided for distinct rules sharing id %q: %q == %q", ruleOne.id, keyOne, keyTwo)
+	}
+}
+
+func TestTransitiveCacheDoesNotConflateSharedIDRules(t *testing.T) {
+	_, b, _ := buildImportChain()
+
+	matchC := compiledRule{id: "shared", to: []*regexp.Regexp{regexp.MustCompile("^example.com/c$")}}
+	matchNothing := compiledRule{id: "shared", to: []*regexp.Regexp{regexp.MustCompile("^example.com/z$")}}
+
+	var cache transitiveCache
+	chain := cache.chain(ruleCacheKey(0, matchC), matchC, "example.com/b", b)
+	if len(chain) == 0 {
+		t.Fatalf("expected a chain for the rule matching example.com/c, got %v", chain)
+	}
+
+	if chain := cache.chain(ruleCacheKey(1, matchNothing), matchNothing, "example.com/b", b); chain != nil {
+		t.Fatalf("rule with a distinct index returned the other rule's cached chain: %v", chain)
+	}
+}